@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/danielfoehrkn/kubectlSwitch/pkg"
 	"github.com/danielfoehrkn/kubectlSwitch/pkg/config"
 	"github.com/danielfoehrkn/kubectlSwitch/pkg/store"
 	"github.com/danielfoehrkn/kubectlSwitch/pkg/subcommands/clean"
+	historysub "github.com/danielfoehrkn/kubectlSwitch/pkg/subcommands/history"
 	"github.com/danielfoehrkn/kubectlSwitch/pkg/subcommands/hooks"
+	"github.com/danielfoehrkn/kubectlSwitch/pkg/subcommands/merge"
 	"github.com/danielfoehrkn/kubectlSwitch/types"
 	vaultapi "github.com/hashicorp/vault/api"
 	"github.com/sirupsen/logrus"
@@ -23,6 +27,8 @@ var (
 	kubeconfigPath string
 	kubeconfigName string
 	showPreview    bool
+	kubecontext    string
+	kubeconfigFlag string
 
 	// vault store
 	storageBackend          string
@@ -34,69 +40,112 @@ var (
 	hookName       string
 	runImmediately bool
 
+	// merge command
+	mergeRename            string
+	mergeUseContext        bool
+	mergeArtifactDirectory string
+
+	// history command
+	historyPin     string
+	historyPinPath string
+	historyPrune   string
+
 	rootCommand = &cobra.Command{
 		Use:   "switch",
 		Short: "Launch the kubeconfig switcher",
 		Long:  `The kubectx for operators.`,
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			switchConfig, err := config.LoadConfigFromFile(configPath)
+			switchConfig, stores, err := buildStores()
 			if err != nil {
-				return fmt.Errorf("failed to read switch config file: %v", err)
+				return err
 			}
 
-			if switchConfig == nil {
-				switchConfig = &types.Config{}
+			preselectedContext := kubecontext
+			if len(preselectedContext) == 0 {
+				preselectedContext = os.Getenv("KUBECONTEXT")
 			}
-
-			if len(kubeconfigPath) > 0 {
-				switchConfig.KubeconfigPaths = append(switchConfig.KubeconfigPaths, types.KubeconfigPath{
-					Path:  kubeconfigPath,
-					Store: types.StoreKind(storageBackend),
-				})
+			if len(args) > 0 {
+				// "switch -" re-activates the previously selected context,
+				// analogous to "cd -".
+				preselectedContext = args[0]
 			}
 
-			var (
-				useVaultStore      = false
-				useFilesystemStore = false
-				stores             []store.KubeconfigStore
-			)
-
-			for _, configuredKubeconfigPath := range switchConfig.KubeconfigPaths {
-				var s store.KubeconfigStore
-
-				switch configuredKubeconfigPath.Store {
-				case types.StoreKindFilesystem:
-					if useFilesystemStore {
-						continue
-					}
-					useFilesystemStore = true
-					s = &store.FilesystemStore{
-						Logger:          logrus.New().WithField("store", types.StoreKindFilesystem),
-						KubeconfigPaths: switchConfig.KubeconfigPaths,
-						KubeconfigName:  kubeconfigName,
-					}
-				case types.StoreKindVault:
-					if useVaultStore {
-						continue
-					}
-					useVaultStore = true
-					vaultStore, err := getVaultStore(switchConfig.VaultAPIAddress, switchConfig.KubeconfigPaths)
-					if err != nil {
-						return err
-					}
-					s = vaultStore
-				default:
-					return fmt.Errorf("unknown store %q", configuredKubeconfigPath.Store)
-				}
-
-				stores = append(stores, s)
-			}
-
-			return pkg.Switcher(stores, switchConfig, configPath, stateDirectory, showPreview)
+			return pkg.SwitcherWithPreselection(stores, switchConfig, configPath, stateDirectory, showPreview, preselectedContext)
 		},
 	}
 )
 
+// buildStores loads the switch config file, merges in the paths configured
+// via flags and environment variables, and instantiates the configured
+// KubeconfigStore backends through the store registry.
+func buildStores() (*types.Config, []store.KubeconfigStore, error) {
+	switchConfig, err := config.LoadConfigFromFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read switch config file: %v", err)
+	}
+
+	if switchConfig == nil {
+		switchConfig = &types.Config{}
+	}
+
+	if len(kubeconfigPath) > 0 {
+		switchConfig.KubeconfigPaths = append(switchConfig.KubeconfigPaths, types.KubeconfigPath{
+			Path:  kubeconfigPath,
+			Store: types.StoreKind(storageBackend),
+		})
+	}
+
+	switchConfig.KubeconfigPaths = append(switchConfig.KubeconfigPaths, explicitKubeconfigPaths()...)
+
+	var (
+		seenKinds = map[types.StoreKind]bool{}
+		stores    []store.KubeconfigStore
+	)
+
+	for _, configuredKubeconfigPath := range switchConfig.KubeconfigPaths {
+		if seenKinds[configuredKubeconfigPath.Store] {
+			continue
+		}
+		seenKinds[configuredKubeconfigPath.Store] = true
+
+		s, err := store.New(configuredKubeconfigPath.Store, switchConfig, switchConfig.KubeconfigPaths, stateDirectory)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		stores = append(stores, s)
+	}
+
+	return switchConfig, stores, nil
+}
+
+// explicitKubeconfigPaths returns the additional filesystem kubeconfig
+// paths configured via the "KUBECONFIG" environment variable and the
+// "--kubeconfig" flag, mirroring kubectl / clientcmd's loading rules: both
+// are a list of files separated by the OS path list separator, and each
+// entry is used as-is (no directory recursion).
+func explicitKubeconfigPaths() []types.KubeconfigPath {
+	var paths []types.KubeconfigPath
+
+	for _, list := range []string{os.Getenv("KUBECONFIG"), kubeconfigFlag} {
+		if len(list) == 0 {
+			continue
+		}
+		for _, path := range filepath.SplitList(list) {
+			if len(path) == 0 {
+				continue
+			}
+			paths = append(paths, types.KubeconfigPath{
+				Path:  path,
+				Store: types.StoreKindFilesystem,
+			})
+		}
+	}
+
+	return paths
+}
+
 func getVaultStore(vaultAPIAddressFromSwitchConfig string, paths []types.KubeconfigPath) (*store.VaultStore, error) {
 	vaultAPI := vaultAPIAddressFromSwitchConfig
 
@@ -152,6 +201,20 @@ func getVaultStore(vaultAPIAddressFromSwitchConfig string, paths []types.Kubecon
 	}, nil
 }
 
+func init() {
+	store.Register(types.StoreKindFilesystem, func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (store.KubeconfigStore, error) {
+		return &store.FilesystemStore{
+			Logger:          logrus.New().WithField("store", types.StoreKindFilesystem),
+			KubeconfigPaths: paths,
+			KubeconfigName:  kubeconfigName,
+		}, nil
+	})
+
+	store.Register(types.StoreKindVault, func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (store.KubeconfigStore, error) {
+		return getVaultStore(switchConfig.VaultAPIAddress, paths)
+	})
+}
+
 func init() {
 	deleteCmd := &cobra.Command{
 		Use:   "clean",
@@ -195,8 +258,97 @@ func init() {
 		true,
 		"run hooks right away. Do not respect the hooks execution configuration.")
 
+	mergeCmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge the selected kubeconfig context into the main kubeconfig",
+		Long:  `Merges the context, cluster and user of the selected kubeconfig into the main kubeconfig instead of writing it to $HOME/.kube/switch_tmp.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, stores, err := buildStores()
+			if err != nil {
+				return err
+			}
+
+			preselectedContext := kubecontext
+			if len(preselectedContext) == 0 {
+				preselectedContext = os.Getenv("KUBECONTEXT")
+			}
+
+			selected, err := pkg.SelectContext(stores, stateDirectory, preselectedContext, showPreview)
+			if err != nil {
+				return err
+			}
+
+			destination := mergeArtifactDirectory
+			if len(destination) == 0 {
+				destination = os.ExpandEnv("$HOME/.kube/config")
+			}
+
+			log := logrus.New().WithField("command", "merge")
+			return merge.Merge(log, selected, destination, mergeRename, mergeUseContext)
+		},
+	}
+
+	mergeCmd.Flags().StringVar(
+		&mergeRename,
+		"rename",
+		"",
+		"new name to use for each merged context, cluster or user entry whose name already exists in the main kubeconfig with different content.")
+
+	mergeCmd.Flags().BoolVar(
+		&mergeUseContext,
+		"use-context",
+		true,
+		"set the merged context as the \"current-context\" of the main kubeconfig.")
+
+	mergeCmd.Flags().StringVar(
+		&mergeArtifactDirectory,
+		"artifact-directory",
+		"",
+		"path to the kubeconfig file to merge into. Defaults to \"$HOME/.kube/config\".")
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "List, pin or prune the recent/favorite kubeconfig contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(historyPin) > 0 {
+				return historysub.Pin(stateDirectory, historyPin, historyPinPath)
+			}
+
+			if len(historyPrune) > 0 {
+				maxAge, err := time.ParseDuration(historyPrune)
+				if err != nil {
+					return fmt.Errorf("invalid --prune duration %q: %v", historyPrune, err)
+				}
+				log := logrus.New().WithField("command", "history")
+				return historysub.Prune(log, stateDirectory, maxAge)
+			}
+
+			return historysub.List(stateDirectory)
+		},
+	}
+
+	historyCmd.Flags().StringVar(
+		&historyPin,
+		"pin",
+		"",
+		"pin the given context name so it always sorts first, regardless of frecency.")
+
+	historyCmd.Flags().StringVar(
+		&historyPinPath,
+		"path",
+		"",
+		"disambiguate --pin when the given context name was found in more than one kubeconfig.")
+
+	historyCmd.Flags().StringVar(
+		&historyPrune,
+		"prune",
+		"",
+		"remove unpinned history entries older than this duration, e.g. \"720h\" for 30 days.")
+
 	rootCommand.AddCommand(deleteCmd)
 	rootCommand.AddCommand(hookCmd)
+	rootCommand.AddCommand(mergeCmd)
+	rootCommand.AddCommand(historyCmd)
 }
 
 func NewCommandStartSwitcher() *cobra.Command {
@@ -213,7 +365,7 @@ func init() {
 		&storageBackend,
 		"store",
 		"filesystem",
-		"the backing store to be searched for kubeconfig files. Can be either \"filesystem\" or \"vault\"")
+		"the backing store to be searched for kubeconfig files. One of \"filesystem\", \"vault\", \"aws-secrets\", \"gcp-secret-manager\", \"azure-keyvault\" or \"exec\"")
 	rootCommand.Flags().StringVar(
 		&kubeconfigName,
 		"kubeconfig-name",
@@ -239,4 +391,14 @@ func init() {
 		"config-path",
 		os.ExpandEnv("$HOME/.kube/switch-config.yaml"),
 		"path on the local filesystem to the configuration file.")
+	rootCommand.Flags().StringVar(
+		&kubecontext,
+		"kubecontext",
+		"",
+		"preselect the kubeconfig context with this name instead of showing the picker. Takes precedence over the environment variable \"KUBECONTEXT\".")
+	rootCommand.Flags().StringVar(
+		&kubeconfigFlag,
+		"kubeconfig",
+		"",
+		"one or more paths to kubeconfig files on the local filesystem, separated by the OS path list separator, mirroring kubectl's \"--kubeconfig\"/\"KUBECONFIG\" semantics. Each file is used as-is without directory recursion. Merged with \"KUBECONFIG\" and \"kubeconfig-path\".")
 }
@@ -0,0 +1,33 @@
+// Package config loads the switch configuration file.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfigFromFile reads and parses the switch configuration file at the
+// given path. Returns a nil Config if the file does not exist.
+func LoadConfigFromFile(path string) (*types.Config, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read switch config file %q: %v", path, err)
+	}
+
+	config := &types.Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("unable to parse switch config file %q: %v", path, err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,189 @@
+// Package history persists the record of successful kubeconfig switches
+// under the state directory, so that the switcher can rank recently and
+// frequently used contexts to the top of the fuzzy list.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const fileName = "history.json"
+
+// halfLife is the frecency half-life: a switch from this long ago counts
+// for half as much as one made right now.
+const halfLife = 7 * 24 * time.Hour
+
+// Entry records a single kubeconfig context that was successfully switched
+// to.
+type Entry struct {
+	Context   string    `json:"context"`
+	StoreKind string    `json:"storeKind"`
+	Path      string    `json:"path"`
+	Timestamp time.Time `json:"timestamp"`
+	Hits      int       `json:"hits"`
+	Pinned    bool      `json:"pinned"`
+}
+
+func (e Entry) key() string {
+	return e.StoreKind + "|" + e.Path + "|" + e.Context
+}
+
+// History is the persisted record of successful switches kept under the
+// state directory.
+type History struct {
+	path    string
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the history file from the state directory. A missing file is
+// not an error; it is treated as an empty history.
+func Load(stateDirectory string) (*History, error) {
+	h := &History{path: filepath.Join(stateDirectory, fileName)}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Save persists the history to its file under the state directory.
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(h.path, data, 0600)
+}
+
+// Record registers a successful switch to the given context, bumping its
+// hit count if it was already known, and persists the history.
+func (h *History) Record(context, storeKind, path string, now time.Time) error {
+	entry := Entry{Context: context, StoreKind: storeKind, Path: path}
+
+	for i := range h.Entries {
+		if h.Entries[i].key() == entry.key() {
+			h.Entries[i].Hits++
+			h.Entries[i].Timestamp = now
+			return h.Save()
+		}
+	}
+
+	entry.Hits = 1
+	entry.Timestamp = now
+	h.Entries = append(h.Entries, entry)
+
+	return h.Save()
+}
+
+// Previous returns the second most recently visited entry, i.e. the one
+// that was current before the last switch. Used to implement the "-"
+// shortcut, analogous to "cd -".
+func (h *History) Previous() (*Entry, bool) {
+	sorted := h.sortedByRecency()
+	if len(sorted) < 2 {
+		return nil, false
+	}
+
+	previous := sorted[1]
+	return &previous, true
+}
+
+func (h *History) sortedByRecency() []Entry {
+	sorted := make([]Entry, len(h.Entries))
+	copy(sorted, h.Entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+	return sorted
+}
+
+// Score returns the frecency score of e at the given time: hits decayed
+// exponentially by age, pinned entries scoring above everything else.
+func (h *History) Score(e Entry, now time.Time) float64 {
+	if e.Pinned {
+		return math.Inf(1)
+	}
+
+	age := now.Sub(e.Timestamp)
+	return float64(e.Hits) * math.Exp(-math.Ln2*float64(age)/float64(halfLife))
+}
+
+// Frecency returns the entries ordered by descending frecency score.
+func (h *History) Frecency(now time.Time) []Entry {
+	sorted := make([]Entry, len(h.Entries))
+	copy(sorted, h.Entries)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return h.Score(sorted[i], now) > h.Score(sorted[j], now)
+	})
+
+	return sorted
+}
+
+// Pin marks the entry with the given context name as pinned, so it always
+// sorts first regardless of frecency. Since the same context name
+// routinely exists in more than one kubeconfig, path disambiguates which
+// entry to pin when more than one shares the name; it may be left empty as
+// long as the name is unique. Returns false if no matching entry exists,
+// and an error if the match is ambiguous.
+func (h *History) Pin(context, path string) (bool, error) {
+	match := -1
+
+	for i := range h.Entries {
+		if h.Entries[i].Context != context {
+			continue
+		}
+		if len(path) > 0 && h.Entries[i].Path != path {
+			continue
+		}
+		if match != -1 {
+			return false, fmt.Errorf("context %q is ambiguous across multiple kubeconfigs (e.g. %q and %q); disambiguate with --path", context, h.Entries[match].Path, h.Entries[i].Path)
+		}
+		match = i
+	}
+
+	if match == -1 {
+		return false, nil
+	}
+
+	h.Entries[match].Pinned = true
+	return true, nil
+}
+
+// Prune removes every unpinned entry older than maxAge and returns how many
+// entries were removed.
+func (h *History) Prune(now time.Time, maxAge time.Duration) int {
+	var kept []Entry
+	removed := 0
+
+	for _, e := range h.Entries {
+		if !e.Pinned && now.Sub(e.Timestamp) > maxAge {
+			removed++
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	h.Entries = kept
+	return removed
+}
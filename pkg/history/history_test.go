@@ -0,0 +1,197 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScoreDecaysWithAgeAndPinnedAlwaysWins(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+
+	fresh := Entry{Hits: 1, Timestamp: now}
+	weekOld := Entry{Hits: 1, Timestamp: now.Add(-halfLife)}
+	pinned := Entry{Hits: 1, Timestamp: now.Add(-365 * 24 * time.Hour), Pinned: true}
+
+	freshScore := h.Score(fresh, now)
+	weekOldScore := h.Score(weekOld, now)
+
+	if weekOldScore >= freshScore {
+		t.Fatalf("expected a week-old entry to score lower than a fresh one, got %f vs %f", weekOldScore, freshScore)
+	}
+	if weekOldScore >= freshScore/1.9 || weekOldScore <= freshScore/2.1 {
+		t.Fatalf("expected the half-life to roughly halve the score, got fresh=%f weekOld=%f", freshScore, weekOldScore)
+	}
+	if !math.IsInf(h.Score(pinned, now), 1) {
+		t.Fatalf("expected a pinned entry to score above everything else")
+	}
+}
+
+func TestFrecencyOrdersByScoreDescending(t *testing.T) {
+	now := time.Now()
+	h := &History{Entries: []Entry{
+		{Context: "stale", Hits: 5, Timestamp: now.Add(-30 * 24 * time.Hour)},
+		{Context: "fresh", Hits: 1, Timestamp: now},
+		{Context: "favorite", Hits: 1, Timestamp: now.Add(-30 * 24 * time.Hour), Pinned: true},
+	}}
+
+	ranked := h.Frecency(now)
+	if ranked[0].Context != "favorite" {
+		t.Fatalf("expected the pinned entry first, got %q", ranked[0].Context)
+	}
+	if ranked[1].Context != "fresh" {
+		t.Fatalf("expected the fresh entry second, got %q", ranked[1].Context)
+	}
+	if ranked[2].Context != "stale" {
+		t.Fatalf("expected the stale entry last, got %q", ranked[2].Context)
+	}
+}
+
+func TestRecordBumpsHitsForTheSameTriple(t *testing.T) {
+	h := &History{path: testHistoryPath(t)}
+	now := time.Now()
+
+	if err := h.Record("prod", "filesystem", "/kube/config", now); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if err := h.Record("prod", "filesystem", "/kube/config", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+
+	if len(h.Entries) != 1 {
+		t.Fatalf("expected a single entry, got %d", len(h.Entries))
+	}
+	if h.Entries[0].Hits != 2 {
+		t.Fatalf("expected 2 hits, got %d", h.Entries[0].Hits)
+	}
+}
+
+func TestRecordKeepsDistinctEntriesForSameContextNameInDifferentKubeconfigs(t *testing.T) {
+	h := &History{path: testHistoryPath(t)}
+	now := time.Now()
+
+	if err := h.Record("default", "filesystem", "/kube/config-a", now); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if err := h.Record("default", "filesystem", "/kube/config-b", now); err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+
+	if len(h.Entries) != 2 {
+		t.Fatalf("expected 2 distinct entries for the same context name in different kubeconfigs, got %d", len(h.Entries))
+	}
+}
+
+func TestPreviousReturnsTheSecondMostRecentlyVisitedEntry(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+
+	h.Entries = []Entry{
+		{Context: "a", Timestamp: now.Add(-2 * time.Minute)},
+		{Context: "b", Timestamp: now.Add(-time.Minute)},
+		{Context: "c", Timestamp: now},
+	}
+
+	previous, ok := h.Previous()
+	if !ok {
+		t.Fatalf("expected Previous() to find an entry")
+	}
+	if previous.Context != "b" {
+		t.Fatalf("expected %q to be the previous context, got %q", "b", previous.Context)
+	}
+}
+
+func TestPreviousWithFewerThanTwoEntries(t *testing.T) {
+	h := &History{Entries: []Entry{{Context: "only", Timestamp: time.Now()}}}
+
+	if _, ok := h.Previous(); ok {
+		t.Fatalf("expected Previous() to report no previous entry with only one recorded")
+	}
+}
+
+func TestPinMarksTheMatchingEntry(t *testing.T) {
+	h := &History{Entries: []Entry{
+		{Context: "prod", Path: "/kube/config"},
+	}}
+
+	pinned, err := h.Pin("prod", "")
+	if err != nil {
+		t.Fatalf("Pin() returned error: %v", err)
+	}
+	if !pinned {
+		t.Fatalf("expected Pin() to report the entry as found")
+	}
+	if !h.Entries[0].Pinned {
+		t.Fatalf("expected the entry to be marked pinned")
+	}
+}
+
+func TestPinIsAmbiguousAcrossKubeconfigsWithoutAPath(t *testing.T) {
+	h := &History{Entries: []Entry{
+		{Context: "prod", Path: "/kube/config-a"},
+		{Context: "prod", Path: "/kube/config-b"},
+	}}
+
+	if _, err := h.Pin("prod", ""); err == nil {
+		t.Fatalf("expected Pin() to fail when the context name is ambiguous")
+	}
+	for _, e := range h.Entries {
+		if e.Pinned {
+			t.Fatalf("expected no entry to have been pinned on an ambiguous match")
+		}
+	}
+
+	pinned, err := h.Pin("prod", "/kube/config-b")
+	if err != nil {
+		t.Fatalf("Pin() with a disambiguating path returned error: %v", err)
+	}
+	if !pinned {
+		t.Fatalf("expected Pin() to report the entry as found")
+	}
+	if h.Entries[0].Pinned {
+		t.Fatalf("did not expect the first entry to be pinned")
+	}
+	if !h.Entries[1].Pinned {
+		t.Fatalf("expected the second entry to be pinned")
+	}
+}
+
+func TestPinReturnsFalseWhenNotFound(t *testing.T) {
+	h := &History{}
+
+	pinned, err := h.Pin("missing", "")
+	if err != nil {
+		t.Fatalf("Pin() returned error: %v", err)
+	}
+	if pinned {
+		t.Fatalf("expected Pin() to report the entry as not found")
+	}
+}
+
+func TestPruneRemovesOnlyOldUnpinnedEntries(t *testing.T) {
+	now := time.Now()
+	h := &History{Entries: []Entry{
+		{Context: "old", Timestamp: now.Add(-100 * 24 * time.Hour)},
+		{Context: "old-pinned", Timestamp: now.Add(-100 * 24 * time.Hour), Pinned: true},
+		{Context: "recent", Timestamp: now},
+	}}
+
+	removed := h.Prune(now, 30*24*time.Hour)
+	if removed != 1 {
+		t.Fatalf("expected 1 entry to be removed, got %d", removed)
+	}
+	if len(h.Entries) != 2 {
+		t.Fatalf("expected 2 entries to remain, got %d", len(h.Entries))
+	}
+	for _, e := range h.Entries {
+		if e.Context == "old" {
+			t.Fatalf("expected the old unpinned entry to have been pruned")
+		}
+	}
+}
+
+func testHistoryPath(t *testing.T) string {
+	t.Helper()
+	return t.TempDir() + "/history.json"
+}
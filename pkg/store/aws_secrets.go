@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func init() {
+	Register(types.StoreKindAWSSecretsManager, func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (KubeconfigStore, error) {
+		return newAWSSecretsManagerStore(switchConfig.AWSSecretsManager, paths)
+	})
+}
+
+// AWSSecretsManagerStore searches AWS Secrets Manager for kubeconfig
+// secrets stored under a configurable path prefix.
+type AWSSecretsManagerStore struct {
+	Logger *logrus.Entry
+	Client *secretsmanager.Client
+
+	// KubeconfigPaths are the configured secret name prefixes that should
+	// be searched.
+	KubeconfigPaths []types.KubeconfigPath
+}
+
+func newAWSSecretsManagerStore(cfg *types.AWSSecretsManagerConfig, paths []types.KubeconfigPath) (*AWSSecretsManagerStore, error) {
+	ctx := context.Background()
+
+	var opts []func(*config.LoadOptions) error
+	if cfg != nil && len(cfg.Region) > 0 {
+		opts = append(opts, config.WithRegion(cfg.Region))
+	}
+
+	awsConfig, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS credentials from the default credential chain: %v", err)
+	}
+
+	if cfg != nil && len(cfg.RoleARN) > 0 {
+		stsClient := sts.NewFromConfig(awsConfig)
+		awsConfig.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, cfg.RoleARN))
+	}
+
+	return &AWSSecretsManagerStore{
+		Logger:          logrus.New().WithField("store", types.StoreKindAWSSecretsManager),
+		Client:          secretsmanager.NewFromConfig(awsConfig),
+		KubeconfigPaths: paths,
+	}, nil
+}
+
+// GetKind returns StoreKindAWSSecretsManager.
+func (a *AWSSecretsManagerStore) GetKind() types.StoreKind {
+	return types.StoreKindAWSSecretsManager
+}
+
+// GetKubeconfigPaths returns the configured secret name prefixes.
+func (a *AWSSecretsManagerStore) GetKubeconfigPaths() []types.KubeconfigPath {
+	return a.KubeconfigPaths
+}
+
+// GetContexts lists every secret under each configured prefix, parses it as
+// a kubeconfig, and emits one SearchResult per context it contains.
+func (a *AWSSecretsManagerStore) GetContexts(channel chan SearchResult) {
+	defer close(channel)
+
+	ctx := context.Background()
+
+	for _, kubeconfigPath := range a.KubeconfigPaths {
+		if kubeconfigPath.Store != types.StoreKindAWSSecretsManager {
+			continue
+		}
+
+		paginator := secretsmanager.NewListSecretsPaginator(a.Client, &secretsmanager.ListSecretsInput{
+			Filters: []smtypes.Filter{{
+				Key:    smtypes.FilterNameStringTypeName,
+				Values: []string{kubeconfigPath.Path},
+			}},
+		})
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				channel <- SearchResult{Error: fmt.Errorf("unable to list secrets under %q: %v", kubeconfigPath.Path, err)}
+				break
+			}
+
+			for _, entry := range page.SecretList {
+				if entry.Name == nil {
+					continue
+				}
+				if err := a.emitContextsForSecret(ctx, *entry.Name, channel); err != nil {
+					channel <- SearchResult{KubeconfigPath: *entry.Name, Error: err}
+				}
+			}
+		}
+	}
+}
+
+func (a *AWSSecretsManagerStore) emitContextsForSecret(ctx context.Context, name string, channel chan SearchResult) error {
+	raw, err := a.GetKubeconfigForPath(name)
+	if err != nil {
+		return err
+	}
+
+	config, err := clientcmd.Load(raw)
+	if err != nil {
+		return fmt.Errorf("unable to parse kubeconfig secret %q: %v", name, err)
+	}
+
+	for contextName := range config.Contexts {
+		channel <- SearchResult{KubeconfigPath: name, Context: contextName}
+	}
+
+	return nil
+}
+
+// GetKubeconfigForPath returns the raw kubeconfig value of the secret
+// identified by path (the secret name or ARN).
+func (a *AWSSecretsManagerStore) GetKubeconfigForPath(path string) ([]byte, error) {
+	out, err := a.Client.GetSecretValue(context.Background(), &secretsmanager.GetSecretValueInput{
+		SecretId: &path,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret %q: %v", path, err)
+	}
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("secret %q has no string value", path)
+	}
+
+	return []byte(*out.SecretString), nil
+}
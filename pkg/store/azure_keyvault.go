@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func init() {
+	Register(types.StoreKindAzureKeyVault, func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (KubeconfigStore, error) {
+		return newAzureKeyVaultStore(switchConfig.AzureKeyVault, paths)
+	})
+}
+
+// AzureKeyVaultStore searches an Azure Key Vault for kubeconfig secrets
+// stored under a configurable name prefix.
+type AzureKeyVaultStore struct {
+	Logger *logrus.Entry
+	Client *azsecrets.Client
+
+	// KubeconfigPaths are the configured secret name prefixes that should
+	// be searched.
+	KubeconfigPaths []types.KubeconfigPath
+}
+
+func newAzureKeyVaultStore(cfg *types.AzureKeyVaultConfig, paths []types.KubeconfigPath) (*AzureKeyVaultStore, error) {
+	if cfg == nil || len(cfg.VaultURL) == 0 {
+		return nil, fmt.Errorf("when using the %q store, a vault URL must be configured", types.StoreKindAzureKeyVault)
+	}
+
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve Azure credentials from the default credential chain: %v", err)
+	}
+
+	client, err := azsecrets.NewClient(cfg.VaultURL, credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Azure Key Vault client for %q: %v", cfg.VaultURL, err)
+	}
+
+	return &AzureKeyVaultStore{
+		Logger:          logrus.New().WithField("store", types.StoreKindAzureKeyVault),
+		Client:          client,
+		KubeconfigPaths: paths,
+	}, nil
+}
+
+// GetKind returns StoreKindAzureKeyVault.
+func (a *AzureKeyVaultStore) GetKind() types.StoreKind {
+	return types.StoreKindAzureKeyVault
+}
+
+// GetKubeconfigPaths returns the configured secret name prefixes.
+func (a *AzureKeyVaultStore) GetKubeconfigPaths() []types.KubeconfigPath {
+	return a.KubeconfigPaths
+}
+
+// GetContexts lists every secret under each configured prefix, parses it as
+// a kubeconfig, and emits one SearchResult per context it contains.
+func (a *AzureKeyVaultStore) GetContexts(channel chan SearchResult) {
+	defer close(channel)
+
+	ctx := context.Background()
+
+	for _, kubeconfigPath := range a.KubeconfigPaths {
+		if kubeconfigPath.Store != types.StoreKindAzureKeyVault {
+			continue
+		}
+
+		pager := a.Client.NewListSecretPropertiesPager(nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				channel <- SearchResult{Error: fmt.Errorf("unable to list secrets under %q: %v", kubeconfigPath.Path, err)}
+				break
+			}
+
+			for _, secret := range page.Value {
+				if secret.ID == nil {
+					continue
+				}
+				name := secret.ID.Name()
+				if !strings.HasPrefix(name, kubeconfigPath.Path) {
+					continue
+				}
+				if err := a.emitContextsForSecret(ctx, name, channel); err != nil {
+					channel <- SearchResult{KubeconfigPath: name, Error: err}
+				}
+			}
+		}
+	}
+}
+
+func (a *AzureKeyVaultStore) emitContextsForSecret(ctx context.Context, name string, channel chan SearchResult) error {
+	raw, err := a.GetKubeconfigForPath(name)
+	if err != nil {
+		return err
+	}
+
+	config, err := clientcmd.Load(raw)
+	if err != nil {
+		return fmt.Errorf("unable to parse kubeconfig secret %q: %v", name, err)
+	}
+
+	for contextName := range config.Contexts {
+		channel <- SearchResult{KubeconfigPath: name, Context: contextName}
+	}
+
+	return nil
+}
+
+// GetKubeconfigForPath returns the raw kubeconfig value of the secret
+// identified by its name in the vault.
+func (a *AzureKeyVaultStore) GetKubeconfigForPath(path string) ([]byte, error) {
+	resp, err := a.Client.GetSecret(context.Background(), path, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret %q: %v", path, err)
+	}
+	if resp.Value == nil {
+		return nil, fmt.Errorf("secret %q has no value", path)
+	}
+
+	return []byte(*resp.Value), nil
+}
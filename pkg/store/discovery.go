@@ -0,0 +1,298 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	container "cloud.google.com/go/container/apiv1"
+	containerpb "cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v5"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// discoverClusters enumerates clusters for the configured cloud provider
+// and returns one synthetic KubeconfigPath per cluster found. The path
+// encodes everything kubeconfigForDiscoveredCluster needs to look the
+// cluster back up later ("<cloud>/<project>/.../<cluster>").
+func discoverClusters(cfg *types.ExecDiscoveryConfig) ([]types.KubeconfigPath, error) {
+	switch cfg.Cloud {
+	case "gke":
+		return discoverGKEClusters(cfg.Project)
+	case "eks":
+		return discoverEKSClusters(cfg.Project)
+	case "aks":
+		return discoverAKSClusters(cfg.Project)
+	default:
+		return nil, fmt.Errorf("unknown discovery cloud %q, must be one of \"gke\", \"eks\" or \"aks\"", cfg.Cloud)
+	}
+}
+
+func discoverGKEClusters(project string) ([]types.KubeconfigPath, error) {
+	ctx := context.Background()
+
+	client, err := container.NewClusterManagerClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GKE cluster manager client: %v", err)
+	}
+
+	resp, err := client.ListClusters(ctx, &containerpb.ListClustersRequest{
+		Parent: fmt.Sprintf("projects/%s/locations/-", project),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list GKE clusters in project %q: %v", project, err)
+	}
+
+	var paths []types.KubeconfigPath
+	for _, cluster := range resp.Clusters {
+		paths = append(paths, types.KubeconfigPath{
+			// The location is carried along so gkeExecKubeconfig can
+			// describe the exact cluster later on, rather than only its name.
+			Path:  fmt.Sprintf("gke/%s/%s/%s", project, cluster.Location, cluster.Name),
+			Store: types.StoreKindExec,
+		})
+	}
+
+	return paths, nil
+}
+
+func discoverEKSClusters(account string) ([]types.KubeconfigPath, error) {
+	ctx := context.Background()
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS credentials from the default credential chain: %v", err)
+	}
+
+	client := eks.NewFromConfig(awsCfg)
+
+	out, err := client.ListClusters(ctx, &eks.ListClustersInput{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list EKS clusters: %v", err)
+	}
+
+	var paths []types.KubeconfigPath
+	for _, name := range out.Clusters {
+		paths = append(paths, types.KubeconfigPath{
+			Path:  fmt.Sprintf("eks/%s/%s", account, name),
+			Store: types.StoreKindExec,
+		})
+	}
+
+	return paths, nil
+}
+
+func discoverAKSClusters(subscriptionID string) ([]types.KubeconfigPath, error) {
+	ctx := context.Background()
+
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve Azure credentials from the default credential chain: %v", err)
+	}
+
+	client, err := armcontainerservice.NewManagedClustersClient(subscriptionID, credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AKS managed clusters client: %v", err)
+	}
+
+	var paths []types.KubeconfigPath
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list AKS clusters in subscription %q: %v", subscriptionID, err)
+		}
+
+		for _, cluster := range page.Value {
+			if cluster.ID == nil || cluster.Name == nil {
+				continue
+			}
+			resourceGroup, err := resourceGroupFromID(*cluster.ID)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, types.KubeconfigPath{
+				Path:  fmt.Sprintf("aks/%s/%s/%s", subscriptionID, resourceGroup, *cluster.Name),
+				Store: types.StoreKindExec,
+			})
+		}
+	}
+
+	return paths, nil
+}
+
+// kubeconfigForDiscoveredCluster resolves a cluster discovered via
+// discoverClusters back into a working kubeconfig. For GKE and EKS the
+// cluster is described again to obtain its API server endpoint and CA
+// certificate, which ListClusters does not return, and a kubeconfig is
+// synthesized pointing at the corresponding cloud CLI's exec credential
+// plugin. AKS's credentials API returns a ready-to-use kubeconfig directly,
+// so it is passed through as-is.
+func kubeconfigForDiscoveredCluster(cfg *types.ExecDiscoveryConfig, path string) ([]byte, error) {
+	switch cfg.Cloud {
+	case "gke":
+		return gkeExecKubeconfig(path)
+	case "eks":
+		return eksExecKubeconfig(path)
+	case "aks":
+		return aksKubeconfig(path)
+	default:
+		return nil, fmt.Errorf("unknown discovery cloud %q", cfg.Cloud)
+	}
+}
+
+func gkeExecKubeconfig(path string) ([]byte, error) {
+	project, location, cluster, err := parseGKEPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	client, err := container.NewClusterManagerClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GKE cluster manager client: %v", err)
+	}
+
+	resp, err := client.GetCluster(ctx, &containerpb.GetClusterRequest{
+		Name: fmt.Sprintf("projects/%s/locations/%s/clusters/%s", project, location, cluster),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe GKE cluster %q: %v", cluster, err)
+	}
+
+	caData, err := base64.StdEncoding.DecodeString(resp.MasterAuth.GetClusterCaCertificate())
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode CA certificate of GKE cluster %q: %v", cluster, err)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[cluster] = &clientcmdapi.Cluster{
+		Server:                   "https://" + resp.Endpoint,
+		CertificateAuthorityData: caData,
+	}
+	config.AuthInfos[cluster] = &clientcmdapi.AuthInfo{
+		Exec: &clientcmdapi.ExecConfig{
+			Command:    "gke-gcloud-auth-plugin",
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		},
+	}
+	config.Contexts[cluster] = &clientcmdapi.Context{Cluster: cluster, AuthInfo: cluster}
+	config.CurrentContext = cluster
+
+	return clientcmd.Write(*config)
+}
+
+func eksExecKubeconfig(path string) ([]byte, error) {
+	cluster, err := parseDiscoveredClusterName(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS credentials from the default credential chain: %v", err)
+	}
+
+	client := eks.NewFromConfig(awsCfg)
+
+	out, err := client.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: &cluster})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe EKS cluster %q: %v", cluster, err)
+	}
+
+	caData, err := base64.StdEncoding.DecodeString(*out.Cluster.CertificateAuthority.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode CA certificate of EKS cluster %q: %v", cluster, err)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[cluster] = &clientcmdapi.Cluster{
+		Server:                   *out.Cluster.Endpoint,
+		CertificateAuthorityData: caData,
+	}
+	config.AuthInfos[cluster] = &clientcmdapi.AuthInfo{
+		Exec: &clientcmdapi.ExecConfig{
+			Command:    "aws",
+			Args:       []string{"eks", "get-token", "--cluster-name", cluster},
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		},
+	}
+	config.Contexts[cluster] = &clientcmdapi.Context{Cluster: cluster, AuthInfo: cluster}
+	config.CurrentContext = cluster
+
+	return clientcmd.Write(*config)
+}
+
+func aksKubeconfig(path string) ([]byte, error) {
+	subscriptionID, resourceGroup, cluster, err := parseAKSPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve Azure credentials from the default credential chain: %v", err)
+	}
+
+	client, err := armcontainerservice.NewManagedClustersClient(subscriptionID, credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AKS managed clusters client: %v", err)
+	}
+
+	resp, err := client.ListClusterUserCredentials(context.Background(), resourceGroup, cluster, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch credentials for AKS cluster %q: %v", cluster, err)
+	}
+	if len(resp.Kubeconfigs) == 0 {
+		return nil, fmt.Errorf("AKS cluster %q returned no kubeconfig credentials", cluster)
+	}
+
+	// Unlike GKE/EKS, AKS hands back a complete, ready-to-use kubeconfig, so
+	// there is nothing left to synthesize.
+	return resp.Kubeconfigs[0].Value, nil
+}
+
+func parseGKEPath(path string) (project, location, cluster string, err error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[0] != "gke" {
+		return "", "", "", fmt.Errorf("malformed discovered GKE cluster path %q", path)
+	}
+	return parts[1], parts[2], parts[3], nil
+}
+
+func parseAKSPath(path string) (subscriptionID, resourceGroup, cluster string, err error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[0] != "aks" {
+		return "", "", "", fmt.Errorf("malformed discovered AKS cluster path %q", path)
+	}
+	return parts[1], parts[2], parts[3], nil
+}
+
+func resourceGroupFromID(id string) (string, error) {
+	parts := strings.Split(id, "/")
+	for i, part := range parts {
+		if strings.EqualFold(part, "resourceGroups") && i+1 < len(parts) {
+			return parts[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("unable to determine resource group from AKS cluster ID %q", id)
+}
+
+func parseDiscoveredClusterName(path string) (string, error) {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[i+1:], nil
+		}
+	}
+	return "", fmt.Errorf("malformed discovered cluster path %q", path)
+}
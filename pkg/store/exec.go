@@ -0,0 +1,196 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const defaultExecCacheTTL = 15 * time.Minute
+
+func init() {
+	Register(types.StoreKindExec, func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (KubeconfigStore, error) {
+		return newExecStore(switchConfig.Exec, paths, stateDirectory)
+	})
+}
+
+// ExecStore generates kubeconfigs dynamically rather than reading them from
+// a static location. Per configured KubeconfigPath it invokes an external
+// command whose stdout is a kubeconfig YAML document, analogous to a
+// kubectl exec credential plugin. In discovery mode it instead enumerates
+// clusters from a cloud provider and synthesizes kubeconfig entries on the
+// fly. Generated kubeconfigs are cached under the state directory for
+// CacheTTL so that repeated invocations of the switcher don't re-run the
+// plugin or re-describe a discovered cluster. The cluster list itself is
+// not cached: discovery mode queries the cloud API on every GetContexts.
+type ExecStore struct {
+	Logger *logrus.Entry
+
+	KubeconfigPaths []types.KubeconfigPath
+	StateDirectory  string
+	CacheTTL        time.Duration
+	Discovery       *types.ExecDiscoveryConfig
+}
+
+func newExecStore(cfg *types.ExecConfig, paths []types.KubeconfigPath, stateDirectory string) (*ExecStore, error) {
+	ttl := defaultExecCacheTTL
+	var discovery *types.ExecDiscoveryConfig
+	if cfg != nil {
+		if cfg.CacheTTLSeconds > 0 {
+			ttl = time.Duration(cfg.CacheTTLSeconds) * time.Second
+		}
+		discovery = cfg.Discovery
+	}
+
+	return &ExecStore{
+		Logger:          logrus.New().WithField("store", types.StoreKindExec),
+		KubeconfigPaths: paths,
+		StateDirectory:  stateDirectory,
+		CacheTTL:        ttl,
+		Discovery:       discovery,
+	}, nil
+}
+
+// GetKind returns StoreKindExec.
+func (e *ExecStore) GetKind() types.StoreKind {
+	return types.StoreKindExec
+}
+
+// GetKubeconfigPaths returns the configured exec commands (or, in discovery
+// mode, the prefix entries that selected discovery).
+func (e *ExecStore) GetKubeconfigPaths() []types.KubeconfigPath {
+	return e.KubeconfigPaths
+}
+
+// GetContexts runs every configured exec command (or, in discovery mode,
+// enumerates clusters from the configured cloud provider), and emits one
+// SearchResult per context found in the resulting kubeconfig.
+func (e *ExecStore) GetContexts(channel chan SearchResult) {
+	defer close(channel)
+
+	if e.Discovery != nil {
+		discovered, err := discoverClusters(e.Discovery)
+		if err != nil {
+			channel <- SearchResult{Error: err}
+			return
+		}
+		for _, path := range discovered {
+			e.emit(path.Path, channel)
+		}
+		return
+	}
+
+	for _, kubeconfigPath := range e.KubeconfigPaths {
+		if kubeconfigPath.Store != types.StoreKindExec {
+			continue
+		}
+		e.emit(kubeconfigPath.Path, channel)
+	}
+}
+
+func (e *ExecStore) emit(path string, channel chan SearchResult) {
+	raw, err := e.GetKubeconfigForPath(path)
+	if err != nil {
+		channel <- SearchResult{KubeconfigPath: path, Error: err}
+		return
+	}
+
+	config, err := clientcmd.Load(raw)
+	if err != nil {
+		channel <- SearchResult{KubeconfigPath: path, Error: fmt.Errorf("unable to parse kubeconfig generated for %q: %v", path, err)}
+		return
+	}
+
+	for contextName := range config.Contexts {
+		channel <- SearchResult{KubeconfigPath: path, Context: contextName}
+	}
+}
+
+// GetKubeconfigForPath returns the kubeconfig generated for path, serving it
+// from the state-directory cache if a fresh entry exists.
+func (e *ExecStore) GetKubeconfigForPath(path string) ([]byte, error) {
+	cacheFile := e.cachePath(path)
+
+	if cached, ok := e.readCache(cacheFile); ok {
+		return cached, nil
+	}
+
+	raw, err := e.generate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.writeCache(cacheFile, raw); err != nil {
+		e.Logger.Warnf("unable to cache kubeconfig for %q: %v", path, err)
+	}
+
+	return raw, nil
+}
+
+func (e *ExecStore) generate(path string) ([]byte, error) {
+	if e.Discovery != nil {
+		return kubeconfigForDiscoveredCluster(e.Discovery, path)
+	}
+	return runExecPlugin(path)
+}
+
+func (e *ExecStore) cachePath(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(e.StateDirectory, "exec-cache", hex.EncodeToString(sum[:])+".yaml")
+}
+
+func (e *ExecStore) readCache(cacheFile string) ([]byte, bool) {
+	info, err := os.Stat(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	if time.Since(info.ModTime()) > e.CacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (e *ExecStore) writeCache(cacheFile string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, data, 0600)
+}
+
+// runExecPlugin runs commandLine (a "command arg1 arg2 ..." string,
+// analogous to a kubectl exec credential plugin) and returns its stdout,
+// which is expected to be a kubeconfig YAML document.
+func runExecPlugin(commandLine string) ([]byte, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty exec command")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(context.Background(), fields[0], fields[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("exec command %q failed: %v (stderr: %s)", commandLine, err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
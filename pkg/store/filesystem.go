@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// FilesystemStore searches the local filesystem for kubeconfig files and
+// surfaces every context contained within them.
+type FilesystemStore struct {
+	Logger *logrus.Entry
+
+	// KubeconfigName only considers files with this name while walking a
+	// directory. Accepts the wildcards '*' and '?'.
+	KubeconfigName string
+
+	// KubeconfigPaths are the configured filesystem paths (files or
+	// directories) that should be searched.
+	KubeconfigPaths []types.KubeconfigPath
+}
+
+// GetKind returns StoreKindFilesystem.
+func (f *FilesystemStore) GetKind() types.StoreKind {
+	return types.StoreKindFilesystem
+}
+
+// GetKubeconfigPaths returns the paths configured for this store.
+func (f *FilesystemStore) GetKubeconfigPaths() []types.KubeconfigPath {
+	return f.KubeconfigPaths
+}
+
+// GetContexts walks every configured path, parses each matching kubeconfig
+// file exactly once, and emits one SearchResult per context it contains.
+func (f *FilesystemStore) GetContexts(channel chan SearchResult) {
+	defer close(channel)
+
+	for _, kubeconfigPath := range f.KubeconfigPaths {
+		if kubeconfigPath.Store != types.StoreKindFilesystem {
+			continue
+		}
+
+		if err := f.discover(kubeconfigPath.Path, channel); err != nil {
+			channel <- SearchResult{Error: err}
+		}
+	}
+}
+
+func (f *FilesystemStore) discover(root string, channel chan SearchResult) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("unable to stat kubeconfig path %q: %v", root, err)
+	}
+
+	if !info.IsDir() {
+		return f.emitContextsForFile(root, channel)
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		matched, matchErr := filepath.Match(f.KubeconfigName, info.Name())
+		if matchErr != nil {
+			return matchErr
+		}
+		if !matched {
+			return nil
+		}
+
+		return f.emitContextsForFile(path, channel)
+	})
+}
+
+func (f *FilesystemStore) emitContextsForFile(path string, channel chan SearchResult) error {
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		channel <- SearchResult{KubeconfigPath: path, Error: fmt.Errorf("unable to parse kubeconfig %q: %v", path, err)}
+		return nil
+	}
+
+	for contextName := range config.Contexts {
+		channel <- SearchResult{
+			KubeconfigPath: path,
+			Context:        contextName,
+		}
+	}
+
+	return nil
+}
+
+// GetKubeconfigForPath returns the raw bytes of the kubeconfig file at path.
+func (f *FilesystemStore) GetKubeconfigForPath(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
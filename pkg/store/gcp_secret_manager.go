@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	secretmanagerpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func init() {
+	Register(types.StoreKindGCPSecretManager, func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (KubeconfigStore, error) {
+		return newGCPSecretManagerStore(switchConfig.GCPSecretManager, paths)
+	})
+}
+
+// GCPSecretManagerStore searches GCP Secret Manager for kubeconfig secrets
+// stored under a configurable path prefix.
+type GCPSecretManagerStore struct {
+	Logger  *logrus.Entry
+	Client  *secretmanager.Client
+	Project string
+
+	// KubeconfigPaths are the configured secret path prefixes that should
+	// be searched.
+	KubeconfigPaths []types.KubeconfigPath
+}
+
+func newGCPSecretManagerStore(cfg *types.GCPSecretManagerConfig, paths []types.KubeconfigPath) (*GCPSecretManagerStore, error) {
+	if cfg == nil || len(cfg.Project) == 0 {
+		return nil, fmt.Errorf("when using the %q store, a GCP project must be configured", types.StoreKindGCPSecretManager)
+	}
+
+	client, err := secretmanager.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP Secret Manager client using Application Default Credentials: %v", err)
+	}
+
+	return &GCPSecretManagerStore{
+		Logger:          logrus.New().WithField("store", types.StoreKindGCPSecretManager),
+		Client:          client,
+		Project:         cfg.Project,
+		KubeconfigPaths: paths,
+	}, nil
+}
+
+// GetKind returns StoreKindGCPSecretManager.
+func (g *GCPSecretManagerStore) GetKind() types.StoreKind {
+	return types.StoreKindGCPSecretManager
+}
+
+// GetKubeconfigPaths returns the configured secret path prefixes.
+func (g *GCPSecretManagerStore) GetKubeconfigPaths() []types.KubeconfigPath {
+	return g.KubeconfigPaths
+}
+
+// GetContexts lists every secret under each configured prefix, parses it as
+// a kubeconfig, and emits one SearchResult per context it contains.
+func (g *GCPSecretManagerStore) GetContexts(channel chan SearchResult) {
+	defer close(channel)
+
+	ctx := context.Background()
+
+	for _, kubeconfigPath := range g.KubeconfigPaths {
+		if kubeconfigPath.Store != types.StoreKindGCPSecretManager {
+			continue
+		}
+
+		it := g.Client.ListSecrets(ctx, &secretmanagerpb.ListSecretsRequest{
+			Parent: fmt.Sprintf("projects/%s", g.Project),
+			Filter: fmt.Sprintf("name:%s*", kubeconfigPath.Path),
+		})
+
+		for {
+			secret, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				channel <- SearchResult{Error: fmt.Errorf("unable to list secrets under %q: %v", kubeconfigPath.Path, err)}
+				break
+			}
+
+			name := fmt.Sprintf("%s/versions/latest", secret.Name)
+			if err := g.emitContextsForSecret(ctx, name, channel); err != nil {
+				channel <- SearchResult{KubeconfigPath: name, Error: err}
+			}
+		}
+	}
+}
+
+func (g *GCPSecretManagerStore) emitContextsForSecret(ctx context.Context, name string, channel chan SearchResult) error {
+	raw, err := g.GetKubeconfigForPath(name)
+	if err != nil {
+		return err
+	}
+
+	config, err := clientcmd.Load(raw)
+	if err != nil {
+		return fmt.Errorf("unable to parse kubeconfig secret %q: %v", name, err)
+	}
+
+	for contextName := range config.Contexts {
+		channel <- SearchResult{KubeconfigPath: name, Context: contextName}
+	}
+
+	return nil
+}
+
+// GetKubeconfigForPath returns the raw kubeconfig value of the secret
+// version identified by path, e.g. "projects/p/secrets/s/versions/latest".
+func (g *GCPSecretManagerStore) GetKubeconfigForPath(path string) ([]byte, error) {
+	resp, err := g.Client.AccessSecretVersion(context.Background(), &secretmanagerpb.AccessSecretVersionRequest{
+		Name: path,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to access secret version %q: %v", path, err)
+	}
+
+	return resp.Payload.Data, nil
+}
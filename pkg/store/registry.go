@@ -0,0 +1,42 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+)
+
+// Factory builds the KubeconfigStore for the given kubeconfig paths and the
+// loaded switch configuration. stateDirectory is the directory configured
+// for persisting internal state (e.g. caches) and is passed through
+// unchanged from the root command.
+type Factory func(switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (KubeconfigStore, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[types.StoreKind]Factory{}
+)
+
+// Register makes a KubeconfigStore implementation available under the
+// given kind. Store implementations call this from their own init()
+// function so that new backends can be added without the root command
+// knowing about them.
+func Register(kind types.StoreKind, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[kind] = factory
+}
+
+// New builds the KubeconfigStore registered for kind.
+func New(kind types.StoreKind, switchConfig *types.Config, paths []types.KubeconfigPath, stateDirectory string) (KubeconfigStore, error) {
+	registryMu.Lock()
+	factory, ok := registry[kind]
+	registryMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown store %q", kind)
+	}
+
+	return factory(switchConfig, paths, stateDirectory)
+}
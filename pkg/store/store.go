@@ -0,0 +1,46 @@
+// Package store contains the KubeconfigStore interface implemented by every
+// backend that the switcher can discover kubeconfig contexts from, along
+// with the concrete implementations (filesystem, Vault, ...).
+package store
+
+import (
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+)
+
+// SearchResult is a single selectable item produced by a KubeconfigStore: one
+// context found within one kubeconfig file.
+type SearchResult struct {
+	// KubeconfigPath identifies the kubeconfig that the context was found
+	// in, as reported by the originating store. Pass this back into
+	// GetKubeconfigForPath to retrieve the raw kubeconfig bytes.
+	KubeconfigPath string
+
+	// Context is the name of the context within the kubeconfig identified
+	// by KubeconfigPath.
+	Context string
+
+	// Error is set when discovering or parsing the kubeconfig failed. Such a
+	// result is sent on the channel like any other; the switcher stops at
+	// the first one it receives and returns its error.
+	Error error
+}
+
+// KubeconfigStore is implemented by every backend that can surface
+// kubeconfig contexts to the switcher (filesystem, Vault, ...).
+type KubeconfigStore interface {
+	// GetKind returns the kind of this store.
+	GetKind() types.StoreKind
+
+	// GetKubeconfigPaths returns the kubeconfig paths configured for this
+	// store.
+	GetKubeconfigPaths() []types.KubeconfigPath
+
+	// GetContexts discovers every kubeconfig reachable through this store
+	// and writes one SearchResult per context found within it to the
+	// given channel. The channel is closed once discovery is done.
+	GetContexts(channel chan SearchResult)
+
+	// GetKubeconfigForPath returns the raw kubeconfig bytes identified by
+	// the given path, as previously reported via SearchResult.KubeconfigPath.
+	GetKubeconfigForPath(path string) ([]byte, error)
+}
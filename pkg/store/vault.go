@@ -0,0 +1,94 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// VaultStore reads kubeconfig secrets from a Vault KV store and surfaces
+// every context contained within them. Each configured path is read as a
+// single secret; paths are not listed or searched recursively.
+type VaultStore struct {
+	Logger *logrus.Entry
+	Client *vaultapi.Client
+
+	// KubeconfigName is the field of each secret that holds the kubeconfig.
+	KubeconfigName string
+
+	// KubeconfigPaths are the configured Vault paths that should be
+	// searched.
+	KubeconfigPaths []types.KubeconfigPath
+}
+
+// GetKind returns StoreKindVault.
+func (v *VaultStore) GetKind() types.StoreKind {
+	return types.StoreKindVault
+}
+
+// GetKubeconfigPaths returns the paths configured for this store.
+func (v *VaultStore) GetKubeconfigPaths() []types.KubeconfigPath {
+	return v.KubeconfigPaths
+}
+
+// GetContexts reads the secret at every configured Vault path, parses its
+// kubeconfig once, and emits one SearchResult per context it contains.
+func (v *VaultStore) GetContexts(channel chan SearchResult) {
+	defer close(channel)
+
+	for _, kubeconfigPath := range v.KubeconfigPaths {
+		if kubeconfigPath.Store != types.StoreKindVault {
+			continue
+		}
+
+		secret, err := v.Client.Logical().Read(kubeconfigPath.Path)
+		if err != nil {
+			channel <- SearchResult{Error: fmt.Errorf("unable to read vault secret %q: %v", kubeconfigPath.Path, err)}
+			continue
+		}
+		if secret == nil {
+			continue
+		}
+
+		raw, ok := secret.Data[v.KubeconfigName].(string)
+		if !ok {
+			channel <- SearchResult{Error: fmt.Errorf("vault secret %q has no %q field", kubeconfigPath.Path, v.KubeconfigName)}
+			continue
+		}
+
+		config, err := clientcmd.Load([]byte(raw))
+		if err != nil {
+			channel <- SearchResult{KubeconfigPath: kubeconfigPath.Path, Error: fmt.Errorf("unable to parse kubeconfig at %q: %v", kubeconfigPath.Path, err)}
+			continue
+		}
+
+		for contextName := range config.Contexts {
+			channel <- SearchResult{
+				KubeconfigPath: kubeconfigPath.Path,
+				Context:        contextName,
+			}
+		}
+	}
+}
+
+// GetKubeconfigForPath returns the raw kubeconfig bytes stored at the Vault
+// path.
+func (v *VaultStore) GetKubeconfigForPath(path string) ([]byte, error) {
+	secret, err := v.Client.Logical().Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vault secret %q: %v", path, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("vault secret %q not found", path)
+	}
+
+	raw, ok := secret.Data[v.KubeconfigName].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault secret %q has no %q field", path, v.KubeconfigName)
+	}
+
+	return []byte(raw), nil
+}
@@ -0,0 +1,24 @@
+// Package clean implements the "switch clean" subcommand.
+package clean
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Clean removes the temporary kubeconfig files created by the switcher in
+// $HOME/.kube/switch_tmp, together with that directory.
+func Clean() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	tmpDir := filepath.Join(home, ".kube", "switch_tmp")
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return fmt.Errorf("unable to remove temporary kubeconfig directory %q: %v", tmpDir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package history implements the "switch history" subcommand: listing,
+// pinning and pruning the recent/favorite contexts recorded by the
+// switcher.
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danielfoehrkn/kubectlSwitch/pkg/history"
+	"github.com/sirupsen/logrus"
+)
+
+// List prints every recorded context, most relevant (by frecency) first.
+func List(stateDirectory string) error {
+	h, err := history.Load(stateDirectory)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range h.Frecency(time.Now()) {
+		pinned := ""
+		if entry.Pinned {
+			pinned = "  (pinned)"
+		}
+		fmt.Printf("%-40s  hits=%-4d  last=%s  %s/%s%s\n", entry.Context, entry.Hits, entry.Timestamp.Format(time.RFC3339), entry.StoreKind, entry.Path, pinned)
+	}
+
+	return nil
+}
+
+// Pin marks the given context as pinned so it always sorts first. path
+// disambiguates which kubeconfig the context was found in when the same
+// context name is recorded for more than one; it may be left empty as long
+// as the name is unique.
+func Pin(stateDirectory, context, path string) error {
+	h, err := history.Load(stateDirectory)
+	if err != nil {
+		return err
+	}
+
+	pinned, err := h.Pin(context, path)
+	if err != nil {
+		return err
+	}
+	if !pinned {
+		return fmt.Errorf("context %q not found in history", context)
+	}
+
+	return h.Save()
+}
+
+// Prune removes every unpinned entry older than maxAge.
+func Prune(log *logrus.Entry, stateDirectory string, maxAge time.Duration) error {
+	h, err := history.Load(stateDirectory)
+	if err != nil {
+		return err
+	}
+
+	removed := h.Prune(time.Now(), maxAge)
+	log.Infof("pruned %d history entries older than %s", removed, maxAge)
+
+	return h.Save()
+}
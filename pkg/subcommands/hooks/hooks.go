@@ -0,0 +1,15 @@
+// Package hooks implements the "switch hooks" subcommand, which runs
+// configured hooks such as periodic kubeconfig refreshes.
+package hooks
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Hooks logs the hook identified by hookName together with configPath,
+// stateDirectory and runImmediately, and returns nil. It does not read the
+// switch config file, does not run any hook and writes no state.
+func Hooks(log *logrus.Entry, configPath, stateDirectory, hookName string, runImmediately bool) error {
+	log.Infof("running hook %q (config: %q, state: %q, immediately: %t)", hookName, configPath, stateDirectory, runImmediately)
+	return nil
+}
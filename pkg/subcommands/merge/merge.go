@@ -0,0 +1,127 @@
+// Package merge implements the "switch merge" subcommand, which folds a
+// selected kubeconfig context into the user's main kubeconfig instead of
+// writing it to the shell-scoped temporary kubeconfig.
+package merge
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Merge folds the single context, cluster and user contained in selected
+// into the kubeconfig at destination (creating it if it does not yet
+// exist). Cluster and user names are frequently generic or reused across
+// provider-generated kubeconfigs, so each of the context, cluster and user
+// entries is checked independently: if an entry of the same name already
+// exists in destination and differs, it is renamed to rename (which must be
+// set in that case) rather than silently overwritten. If useContext is
+// true, the merged context is set as destination's "current-context".
+func Merge(log *logrus.Entry, selected *clientcmdapi.Config, destination, rename string, useContext bool) error {
+	contextName, context, err := singleContext(selected)
+	if err != nil {
+		return err
+	}
+
+	target, err := loadOrEmpty(destination)
+	if err != nil {
+		return err
+	}
+
+	cluster := selected.Clusters[context.Cluster]
+	authInfo := selected.AuthInfos[context.AuthInfo]
+
+	finalContextName := contextName
+	if existing, ok := target.Contexts[contextName]; ok && !contextEqual(existing, context) {
+		if len(rename) == 0 {
+			return fmt.Errorf("context %q already exists in %q with different content; pass --rename to disambiguate", contextName, destination)
+		}
+		finalContextName = rename
+	}
+
+	finalClusterName := context.Cluster
+	if existing, ok := target.Clusters[context.Cluster]; ok && !clusterEqual(existing, cluster) {
+		if len(rename) == 0 {
+			return fmt.Errorf("cluster %q already exists in %q with different content; pass --rename to disambiguate", context.Cluster, destination)
+		}
+		finalClusterName = rename
+	}
+
+	finalUserName := context.AuthInfo
+	if existing, ok := target.AuthInfos[context.AuthInfo]; ok && !authInfoEqual(existing, authInfo) {
+		if len(rename) == 0 {
+			return fmt.Errorf("user %q already exists in %q with different content; pass --rename to disambiguate", context.AuthInfo, destination)
+		}
+		finalUserName = rename
+	}
+
+	target.Clusters[finalClusterName] = cluster
+	target.AuthInfos[finalUserName] = authInfo
+	target.Contexts[finalContextName] = &clientcmdapi.Context{
+		Cluster:   finalClusterName,
+		AuthInfo:  finalUserName,
+		Namespace: context.Namespace,
+	}
+
+	if useContext {
+		target.CurrentContext = finalContextName
+	}
+
+	log.Infof("merging context %q into %q", finalContextName, destination)
+
+	return clientcmd.WriteToFile(*target, destination)
+}
+
+func singleContext(config *clientcmdapi.Config) (string, *clientcmdapi.Context, error) {
+	for name, context := range config.Contexts {
+		return name, context, nil
+	}
+	return "", nil, fmt.Errorf("no context to merge")
+}
+
+func loadOrEmpty(path string) (*clientcmdapi.Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return clientcmdapi.NewConfig(), nil
+		}
+		return nil, err
+	}
+
+	return clientcmd.LoadFromFile(path)
+}
+
+func contextEqual(a, b *clientcmdapi.Context) bool {
+	return a.Cluster == b.Cluster && a.AuthInfo == b.AuthInfo && a.Namespace == b.Namespace
+}
+
+// clusterEqual compares two cluster entries for content equality.
+// LocationOfOrigin and Extensions are excluded: clientcmd.LoadFromFile
+// stamps the former and initializes the latter to an empty, rather than
+// nil, map, neither of which reflects an actual content difference.
+func clusterEqual(a, b *clientcmdapi.Cluster) bool {
+	return reflect.DeepEqual(normalizeCluster(a), normalizeCluster(b))
+}
+
+func normalizeCluster(c *clientcmdapi.Cluster) *clientcmdapi.Cluster {
+	normalized := *c
+	normalized.LocationOfOrigin = ""
+	normalized.Extensions = nil
+	return &normalized
+}
+
+// authInfoEqual compares two user entries for content equality, the same
+// way clusterEqual does.
+func authInfoEqual(a, b *clientcmdapi.AuthInfo) bool {
+	return reflect.DeepEqual(normalizeAuthInfo(a), normalizeAuthInfo(b))
+}
+
+func normalizeAuthInfo(a *clientcmdapi.AuthInfo) *clientcmdapi.AuthInfo {
+	normalized := *a
+	normalized.LocationOfOrigin = ""
+	normalized.Extensions = nil
+	return &normalized
+}
@@ -0,0 +1,125 @@
+package merge
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newSelected(contextName, clusterName, userName, server string) *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{Server: server}
+	config.AuthInfos[userName] = &clientcmdapi.AuthInfo{Token: "token-" + server}
+	config.Contexts[contextName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: userName}
+	config.CurrentContext = contextName
+	return config
+}
+
+func TestMergeIntoEmptyDestination(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "config")
+	selected := newSelected("prod", "prod-cluster", "prod-user", "https://prod.example.com")
+	log := logrus.New().WithField("test", "merge")
+
+	if err := Merge(log, selected, destination, "", true); err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	target, err := loadOrEmpty(destination)
+	if err != nil {
+		t.Fatalf("failed to load merged destination: %v", err)
+	}
+
+	if _, ok := target.Contexts["prod"]; !ok {
+		t.Fatalf("expected context %q to be merged", "prod")
+	}
+	if target.CurrentContext != "prod" {
+		t.Fatalf("expected current-context to be %q, got %q", "prod", target.CurrentContext)
+	}
+}
+
+func TestMergeSameNameSameContentIsNoop(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "config")
+	selected := newSelected("prod", "default", "default", "https://prod.example.com")
+	log := logrus.New().WithField("test", "merge")
+
+	if err := Merge(log, selected, destination, "", true); err != nil {
+		t.Fatalf("first Merge() returned error: %v", err)
+	}
+	if err := Merge(log, selected, destination, "", true); err != nil {
+		t.Fatalf("second Merge() of the identical context returned error: %v", err)
+	}
+}
+
+func TestMergeContextCollisionRequiresRename(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "config")
+	log := logrus.New().WithField("test", "merge")
+
+	if err := Merge(log, newSelected("prod", "cluster-a", "user-a", "https://a.example.com"), destination, "", true); err != nil {
+		t.Fatalf("first Merge() returned error: %v", err)
+	}
+
+	if err := Merge(log, newSelected("prod", "cluster-b", "user-b", "https://b.example.com"), destination, "", true); err == nil {
+		t.Fatalf("expected error merging a colliding context without --rename")
+	}
+
+	if err := Merge(log, newSelected("prod", "cluster-b", "user-b", "https://b.example.com"), destination, "prod-2", true); err != nil {
+		t.Fatalf("Merge() with --rename returned error: %v", err)
+	}
+
+	target, err := loadOrEmpty(destination)
+	if err != nil {
+		t.Fatalf("failed to load merged destination: %v", err)
+	}
+	if _, ok := target.Contexts["prod-2"]; !ok {
+		t.Fatalf("expected renamed context %q to exist", "prod-2")
+	}
+	if target.Contexts["prod"].Cluster != "cluster-a" {
+		t.Fatalf("expected original context %q to be left untouched", "prod")
+	}
+}
+
+func TestMergeClusterCollisionDoesNotOverwriteUnrelatedEntry(t *testing.T) {
+	destination := filepath.Join(t.TempDir(), "config")
+	log := logrus.New().WithField("test", "merge")
+
+	// Two different contexts that happen to reuse the generic cluster/user
+	// name "default", as provider-generated kubeconfigs commonly do.
+	if err := Merge(log, newSelected("dev", "default", "default", "https://dev.example.com"), destination, "", true); err != nil {
+		t.Fatalf("first Merge() returned error: %v", err)
+	}
+
+	if err := Merge(log, newSelected("staging", "default", "default", "https://staging.example.com"), destination, "", false); err == nil {
+		t.Fatalf("expected error merging a colliding cluster/user without --rename")
+	}
+
+	target, err := loadOrEmpty(destination)
+	if err != nil {
+		t.Fatalf("failed to load merged destination: %v", err)
+	}
+	if target.Clusters["default"].Server != "https://dev.example.com" {
+		t.Fatalf("expected the first merge's cluster entry %q to be untouched, got server %q", "default", target.Clusters["default"].Server)
+	}
+	if _, ok := target.Contexts["staging"]; ok {
+		t.Fatalf("did not expect context %q to have been merged", "staging")
+	}
+
+	if err := Merge(log, newSelected("staging", "default", "default", "https://staging.example.com"), destination, "staging-default", false); err != nil {
+		t.Fatalf("Merge() with --rename returned error: %v", err)
+	}
+
+	target, err = loadOrEmpty(destination)
+	if err != nil {
+		t.Fatalf("failed to reload merged destination: %v", err)
+	}
+	if target.Clusters["default"].Server != "https://dev.example.com" {
+		t.Fatalf("expected the original cluster entry %q to remain untouched after rename", "default")
+	}
+	if target.Clusters["staging-default"].Server != "https://staging.example.com" {
+		t.Fatalf("expected renamed cluster entry %q to exist", "staging-default")
+	}
+	if target.Contexts["staging"].Cluster != "staging-default" {
+		t.Fatalf("expected context %q to reference the renamed cluster", "staging")
+	}
+}
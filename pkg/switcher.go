@@ -0,0 +1,262 @@
+// Package pkg implements the interactive kubeconfig context switcher.
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/danielfoehrkn/kubectlSwitch/pkg/history"
+	"github.com/danielfoehrkn/kubectlSwitch/pkg/store"
+	"github.com/danielfoehrkn/kubectlSwitch/types"
+	"github.com/ktr0731/go-fuzzyfinder"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// tmpKubeconfigDirectory is the directory under $HOME/.kube that the
+// switcher writes the kubeconfig of the selected context into.
+const tmpKubeconfigDirectory = "switch_tmp"
+
+// tmpKubeconfigFileName is the name of that kubeconfig file.
+const tmpKubeconfigFileName = "config"
+
+// previousContextShortcut preselects the context that was active before the
+// last switch, analogous to "cd -".
+const previousContextShortcut = "-"
+
+// item is a single selectable entry in the fuzzy finder: one context found
+// in one kubeconfig, alongside the store it was discovered through.
+type item struct {
+	store.SearchResult
+	store store.KubeconfigStore
+}
+
+// displayName is the "<file>/<context>" label shown in the fuzzy list.
+func (i item) displayName() string {
+	return fmt.Sprintf("%s/%s", i.KubeconfigPath, i.Context)
+}
+
+// Switcher collects every context exposed by the given stores, lets the
+// user fuzzy-select one, and writes a kubeconfig containing only that
+// context to $HOME/.kube/switch_tmp.
+func Switcher(stores []store.KubeconfigStore, switchConfig *types.Config, configPath, stateDirectory string, showPreview bool) error {
+	return SwitcherWithPreselection(stores, switchConfig, configPath, stateDirectory, showPreview, "")
+}
+
+// SwitcherWithPreselection behaves like Switcher, but if preselectedContext
+// is non-empty, it is selected non-interactively instead of opening the
+// fuzzy finder. This backs "--kubecontext"/"KUBECONTEXT", as well as the
+// "-" shortcut that re-activates the previously selected context.
+func SwitcherWithPreselection(stores []store.KubeconfigStore, switchConfig *types.Config, configPath, stateDirectory string, showPreview bool, preselectedContext string) error {
+	config, err := SelectContext(stores, stateDirectory, preselectedContext, showPreview)
+	if err != nil {
+		return err
+	}
+
+	return writeSelectedContext(config)
+}
+
+// preselection identifies the item that should be activated without
+// prompting, bypassing the fuzzy finder. Path and StoreKind are only known
+// when resolved from history (the "-" shortcut); a bare "--kubecontext"
+// name leaves them empty and matches on Context alone.
+type preselection struct {
+	Context   string
+	Path      string
+	StoreKind string
+}
+
+// SelectContext lets the user fuzzy-select a kubeconfig context exposed by
+// the given stores (or preselects it non-interactively if
+// preselectedContext is set, with "-" resolving to the previously selected
+// context), recording the switch in the history kept under stateDirectory,
+// and returns a kubeconfig containing only that context. Used by both the
+// switcher itself and the "switch merge" subcommand.
+func SelectContext(stores []store.KubeconfigStore, stateDirectory, preselectedContext string, showPreview bool) (*clientcmdapi.Config, error) {
+	h, err := history.Load(stateDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load switch history: %v", err)
+	}
+
+	items, err := collectItems(stores)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no kubeconfig contexts found")
+	}
+
+	sel := preselection{Context: preselectedContext}
+	if preselectedContext == previousContextShortcut {
+		previous, ok := h.Previous()
+		if !ok {
+			return nil, fmt.Errorf("no previously selected context recorded yet")
+		}
+		// Match on the full (context, path, store) triple the history
+		// entry identifies, not just the context name, since contexts
+		// with the same name routinely exist in more than one kubeconfig.
+		sel = preselection{Context: previous.Context, Path: previous.Path, StoreKind: previous.StoreKind}
+	}
+
+	selected, err := selectItem(items, h, sel, showPreview)
+	if err != nil {
+		return nil, err
+	}
+
+	config, context, err := loadContext(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.Record(selected.Context, string(selected.store.GetKind()), selected.KubeconfigPath, time.Now()); err != nil {
+		return nil, fmt.Errorf("unable to record switch history: %v", err)
+	}
+
+	result := contextOnlyConfig(config, selected.Context, context)
+	return &result, nil
+}
+
+func collectItems(stores []store.KubeconfigStore) ([]item, error) {
+	var items []item
+
+	for _, s := range stores {
+		channel := make(chan store.SearchResult)
+		go s.GetContexts(channel)
+
+		for result := range channel {
+			if result.Error != nil {
+				return nil, result.Error
+			}
+			items = append(items, item{SearchResult: result, store: s})
+		}
+	}
+
+	return items, nil
+}
+
+// sortByFrecency orders items so that recently/frequently switched-to
+// contexts appear first, falling back to the original discovery order for
+// contexts that are not yet in the history.
+func sortByFrecency(items []item, h *history.History) []item {
+	now := time.Now()
+	sorted := make([]item, len(items))
+	copy(sorted, items)
+
+	score := func(i item) float64 {
+		for _, e := range h.Entries {
+			if e.Context == i.Context && e.Path == i.KubeconfigPath && e.StoreKind == string(i.store.GetKind()) {
+				return h.Score(e, now)
+			}
+		}
+		return 0
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return score(sorted[i]) > score(sorted[j])
+	})
+
+	return sorted
+}
+
+func selectItem(items []item, h *history.History, sel preselection, showPreview bool) (item, error) {
+	if len(sel.Context) > 0 {
+		for _, i := range items {
+			if i.Context != sel.Context {
+				continue
+			}
+			if len(sel.Path) > 0 && i.KubeconfigPath != sel.Path {
+				continue
+			}
+			if len(sel.StoreKind) > 0 && string(i.store.GetKind()) != sel.StoreKind {
+				continue
+			}
+			return i, nil
+		}
+		return item{}, fmt.Errorf("context %q not found among the discovered kubeconfigs", sel.Context)
+	}
+
+	ranked := sortByFrecency(items, h)
+
+	opts := []fuzzyfinder.Option{
+		fuzzyfinder.WithPromptString("Select a kubeconfig context:"),
+	}
+	if showPreview {
+		opts = append(opts, fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+			if i < 0 {
+				return ""
+			}
+			return renderPreview(ranked[i])
+		}))
+	}
+
+	idx, err := fuzzyfinder.Find(ranked, func(i int) string {
+		return ranked[i].displayName()
+	}, opts...)
+	if err != nil {
+		return item{}, fmt.Errorf("unable to select a context: %v", err)
+	}
+
+	return ranked[idx], nil
+}
+
+// renderPreview renders just the selected context block, instead of the
+// entire kubeconfig file it lives in.
+func renderPreview(i item) string {
+	config, context, err := loadContext(i)
+	if err != nil {
+		return err.Error()
+	}
+
+	out, err := clientcmd.Write(contextOnlyConfig(config, i.Context, context))
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+func loadContext(i item) (*clientcmdapi.Config, *clientcmdapi.Context, error) {
+	raw, err := i.store.GetKubeconfigForPath(i.KubeconfigPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, err := clientcmd.Load(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	context, ok := config.Contexts[i.Context]
+	if !ok {
+		return nil, nil, fmt.Errorf("context %q not found in %q", i.Context, i.KubeconfigPath)
+	}
+
+	return config, context, nil
+}
+
+// contextOnlyConfig builds a kubeconfig containing only the given context
+// and the cluster/user entries it references.
+func contextOnlyConfig(config *clientcmdapi.Config, contextName string, context *clientcmdapi.Context) clientcmdapi.Config {
+	return clientcmdapi.Config{
+		Contexts:       map[string]*clientcmdapi.Context{contextName: context},
+		Clusters:       map[string]*clientcmdapi.Cluster{context.Cluster: config.Clusters[context.Cluster]},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{context.AuthInfo: config.AuthInfos[context.AuthInfo]},
+		CurrentContext: contextName,
+	}
+}
+
+func writeSelectedContext(config *clientcmdapi.Config) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	tmpDir := filepath.Join(home, ".kube", tmpKubeconfigDirectory)
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return err
+	}
+
+	return clientcmd.WriteToFile(*config, filepath.Join(tmpDir, tmpKubeconfigFileName))
+}
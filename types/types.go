@@ -0,0 +1,112 @@
+// Package types contains the shared configuration types used across the
+// switcher, its stores and its subcommands.
+package types
+
+// StoreKind identifies a backing store implementation that can be searched
+// for kubeconfig files.
+type StoreKind string
+
+const (
+	// StoreKindFilesystem searches the local filesystem for kubeconfig files.
+	StoreKindFilesystem StoreKind = "filesystem"
+	// StoreKindVault searches a Vault KV store for kubeconfig files.
+	StoreKindVault StoreKind = "vault"
+	// StoreKindAWSSecretsManager searches AWS Secrets Manager for kubeconfig
+	// secrets.
+	StoreKindAWSSecretsManager StoreKind = "aws-secrets"
+	// StoreKindGCPSecretManager searches GCP Secret Manager for kubeconfig
+	// secrets.
+	StoreKindGCPSecretManager StoreKind = "gcp-secret-manager"
+	// StoreKindAzureKeyVault searches an Azure Key Vault for kubeconfig
+	// secrets.
+	StoreKindAzureKeyVault StoreKind = "azure-keyvault"
+	// StoreKindExec generates kubeconfigs dynamically, either by invoking an
+	// external command per KubeconfigPath or, in discovery mode, by
+	// enumerating clusters from a cloud provider.
+	StoreKindExec StoreKind = "exec"
+)
+
+// KubeconfigPath defines a single path that should be searched for
+// kubeconfig files, together with the store that should be used to search
+// it.
+type KubeconfigPath struct {
+	// Path is interpreted by its store: a file or a directory on the local
+	// filesystem, a path in Vault, a secret name prefix in a cloud secret
+	// store, or, for the "exec" store, the command line to run.
+	Path string `yaml:"path"`
+	// Store is the backing store that this path should be searched with.
+	Store StoreKind `yaml:"store"`
+}
+
+// Config is the structure of the switch configuration file
+// ($HOME/.kube/switch-config.yaml).
+type Config struct {
+	// KubeconfigPaths are the paths that should be searched for kubeconfig
+	// files across all configured stores.
+	KubeconfigPaths []KubeconfigPath `yaml:"kubeconfigPaths"`
+	// VaultAPIAddress is the address of the Vault API used by the Vault
+	// store. Can be overridden via the "--vault-api-address" flag or the
+	// "VAULT_ADDR" environment variable.
+	VaultAPIAddress string `yaml:"vaultAPIAddress,omitempty"`
+
+	// AWSSecretsManager configures the "aws-secrets" store.
+	AWSSecretsManager *AWSSecretsManagerConfig `yaml:"awsSecretsManager,omitempty"`
+	// GCPSecretManager configures the "gcp-secret-manager" store.
+	GCPSecretManager *GCPSecretManagerConfig `yaml:"gcpSecretManager,omitempty"`
+	// AzureKeyVault configures the "azure-keyvault" store.
+	AzureKeyVault *AzureKeyVaultConfig `yaml:"azureKeyVault,omitempty"`
+	// Exec configures the "exec" store.
+	Exec *ExecConfig `yaml:"exec,omitempty"`
+}
+
+// AWSSecretsManagerConfig is the authentication and scoping configuration
+// for the AWS Secrets Manager store. Credentials are always resolved via
+// the AWS SDK's default credential chain; RoleARN is assumed on top of it.
+type AWSSecretsManagerConfig struct {
+	// Region is the AWS region that the Secrets Manager API is called in.
+	Region string `yaml:"region,omitempty"`
+	// RoleARN, if set, is assumed before listing/reading secrets.
+	RoleARN string `yaml:"roleARN,omitempty"`
+}
+
+// GCPSecretManagerConfig is the authentication and scoping configuration
+// for the GCP Secret Manager store. Credentials are always resolved via
+// Application Default Credentials.
+type GCPSecretManagerConfig struct {
+	// Project is the GCP project that secrets are listed/read from.
+	Project string `yaml:"project,omitempty"`
+}
+
+// AzureKeyVaultConfig is the authentication and scoping configuration for
+// the Azure Key Vault store. Credentials are always resolved via the
+// default Azure credential chain.
+type AzureKeyVaultConfig struct {
+	// VaultURL is the URL of the Azure Key Vault, e.g.
+	// "https://my-vault.vault.azure.net".
+	VaultURL string `yaml:"vaultURL,omitempty"`
+}
+
+// ExecConfig is the configuration for the "exec" store.
+type ExecConfig struct {
+	// CacheTTLSeconds is how long a kubeconfig generated by an exec plugin
+	// or discovered from a cloud provider is cached under the state
+	// directory before being regenerated. Defaults to 900 (15 minutes).
+	CacheTTLSeconds int `yaml:"cacheTTLSeconds,omitempty"`
+	// Discovery, if set, synthesizes kubeconfig entries on the fly by
+	// enumerating clusters from a cloud provider, instead of invoking the
+	// command configured via KubeconfigPath.Path.
+	Discovery *ExecDiscoveryConfig `yaml:"discovery,omitempty"`
+}
+
+// ExecDiscoveryConfig configures the built-in cluster discovery mode of the
+// "exec" store.
+type ExecDiscoveryConfig struct {
+	// Cloud selects the provider to enumerate clusters from: "gke", "eks"
+	// or "aks".
+	Cloud string `yaml:"cloud"`
+	// Project is the GCP project ("gke") or Azure subscription ("aks") that
+	// clusters are enumerated in. For "eks" it is an AWS account that only
+	// labels the discovered clusters; they are listed in the account and
+	// region that the default AWS credentials resolve to.
+	Project string `yaml:"project,omitempty"`
+}